@@ -19,8 +19,12 @@ package source
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/diginfra/plugin-sdk-go/pkg/sdk"
@@ -32,14 +36,19 @@ var (
 
 type builtinInstance struct {
 	BaseInstance
-	shutdown      func()
-	progress      func() (float64, string)
-	ctx           context.Context
-	timeout       time.Duration
-	timeoutTicker *time.Ticker
-	eof           bool
-	eventSize     uint32
-	batchSize     uint32
+	shutdown         func()
+	progress         func() (float64, string)
+	progressReporter func() ProgressReport
+	ctx              context.Context
+	timeout          time.Duration
+	timeoutTicker    *time.Ticker
+	eof              bool
+	eventSize        uint32
+	batchSize        uint32
+	startedAt        time.Time
+	eventsProcessed  uint64 // atomic
+	bytesProcessed   uint64 // atomic
+	lastEventAtNano  int64  // atomic, unix nano; 0 if no event was processed yet
 }
 
 func (s *builtinInstance) Close() {
@@ -51,12 +60,48 @@ func (s *builtinInstance) Close() {
 }
 
 func (s *builtinInstance) Progress(pState sdk.PluginState) (float64, string) {
+	if s.progressReporter != nil {
+		report := s.progressReporter()
+		s.fillAutoFields(&report)
+		return report.Fraction, report.String()
+	}
 	if s.progress != nil {
 		return s.progress()
 	}
 	return 0, ""
 }
 
+// recordEvent updates the automatic EventsProcessed, BytesProcessed and
+// LastEventAt counters exposed through ProgressReport. It is safe for
+// concurrent use, since pull implementations such as the one backing
+// NewParallelPullInstance may process several events at once.
+func (s *builtinInstance) recordEvent(bytes uint64) {
+	atomic.AddUint64(&s.eventsProcessed, 1)
+	atomic.AddUint64(&s.bytesProcessed, bytes)
+	atomic.StoreInt64(&s.lastEventAtNano, time.Now().UnixNano())
+}
+
+// fillAutoFields fills any zero-valued field of report with the value
+// automatically tracked by the instance, so that a custom
+// WithInstanceProgressReporter callback only needs to fill in the fields it
+// actually knows about (typically BytesTotal).
+func (s *builtinInstance) fillAutoFields(report *ProgressReport) {
+	if report.EventsProcessed == 0 {
+		report.EventsProcessed = atomic.LoadUint64(&s.eventsProcessed)
+	}
+	if report.BytesProcessed == 0 {
+		report.BytesProcessed = atomic.LoadUint64(&s.bytesProcessed)
+	}
+	if report.StartedAt.IsZero() {
+		report.StartedAt = s.startedAt
+	}
+	if report.LastEventAt.IsZero() {
+		if nanos := atomic.LoadInt64(&s.lastEventAtNano); nanos != 0 {
+			report.LastEventAt = time.Unix(0, nanos)
+		}
+	}
+}
+
 // WithInstanceContext sets a custom context in the opened event source.
 // If the context is cancelled, the event source is closed and sdk.ErrEOF
 // is returned by the current invocation of NextBatch() and by any subsequent
@@ -108,6 +153,114 @@ func WithInstanceProgress(progress func() (float64, string)) func(*builtinInstan
 	}
 }
 
+// ProgressReport is a structured, machine-readable description of the
+// progress of an opened event source. It extends the plain (float64,
+// string) pair returned by Progress() with the fields a plugin is commonly
+// expected to reinvent by hand: how much has been processed, how much is
+// left, and when it started and last produced something.
+//
+// A zero-valued field means "unknown" and is filled in automatically by
+// builtinInstance from its own event/byte counters where possible; a
+// reporter callback only needs to set the fields it has better knowledge
+// of, such as BytesTotal.
+type ProgressReport struct {
+	// Fraction is the completion ratio in the [0, 1] range, exactly like
+	// the float64 returned by Progress().
+	Fraction float64
+	// BytesProcessed is the number of bytes read or produced so far.
+	BytesProcessed uint64
+	// BytesTotal is the total number of bytes expected, if known.
+	BytesTotal uint64
+	// EventsProcessed is the number of events produced so far.
+	EventsProcessed uint64
+	// StartedAt is when the event source started capturing.
+	StartedAt time.Time
+	// LastEventAt is when the most recent event was produced.
+	LastEventAt time.Time
+	// Labels holds free-form, plugin-specific progress information, such as
+	// a source name or a file path, rendered into the legacy string format.
+	Labels map[string]string
+}
+
+// Throughput returns the average processing rate in bytes per second,
+// derived from BytesProcessed and the time elapsed since StartedAt. The
+// second return value is false when StartedAt is unset or no time has
+// elapsed yet.
+func (r ProgressReport) Throughput() (float64, bool) {
+	if r.StartedAt.IsZero() {
+		return 0, false
+	}
+	elapsed := time.Since(r.StartedAt).Seconds()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	return float64(r.BytesProcessed) / elapsed, true
+}
+
+// ETA estimates the remaining time to process BytesTotal at the current
+// Throughput. The second return value is false when BytesTotal is unknown,
+// already reached, or the throughput cannot be computed.
+func (r ProgressReport) ETA() (time.Duration, bool) {
+	if r.BytesTotal == 0 || r.BytesProcessed >= r.BytesTotal {
+		return 0, false
+	}
+	rate, ok := r.Throughput()
+	if !ok || rate <= 0 {
+		return 0, false
+	}
+	remaining := float64(r.BytesTotal - r.BytesProcessed)
+	return time.Duration(remaining / rate * float64(time.Second)), true
+}
+
+// String formats the report into the legacy plain-text shape returned by
+// Progress(), for plugins and UIs that only understand that component.
+func (r ProgressReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%.2f%%", r.Fraction*100)
+
+	switch {
+	case r.BytesTotal > 0:
+		fmt.Fprintf(&b, ", %d/%d bytes", r.BytesProcessed, r.BytesTotal)
+	case r.BytesProcessed > 0:
+		fmt.Fprintf(&b, ", %d bytes", r.BytesProcessed)
+	}
+
+	if r.EventsProcessed > 0 {
+		fmt.Fprintf(&b, ", %d events", r.EventsProcessed)
+	}
+
+	if eta, ok := r.ETA(); ok {
+		fmt.Fprintf(&b, ", ETA %s", eta.Round(time.Second))
+	}
+
+	if len(r.Labels) > 0 {
+		keys := make([]string, 0, len(r.Labels))
+		for k := range r.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, ", %s=%s", k, r.Labels[k])
+		}
+	}
+
+	return b.String()
+}
+
+// WithInstanceProgressReporter sets a custom callback for the framework to
+// request a structured ProgressReport describing the state of the opened
+// event stream. It supersedes WithInstanceProgress when both are set.
+//
+// Fields left at their zero value are filled in automatically from the
+// EventsProcessed and BytesProcessed counters tracked by the instance
+// itself, so plugin authors only need to report what they know better,
+// typically BytesTotal.
+func WithInstanceProgressReporter(reporter func() ProgressReport) func(*builtinInstance) {
+	return func(s *builtinInstance) {
+		s.progressReporter = reporter
+	}
+}
+
 // PullFunc produces a new event and returns a non-nil error in case of failure.
 //
 // The event data is produced through the sdk.EventWriter interface.
@@ -151,6 +304,7 @@ func NewPullInstance(pull PullFunc, options ...func(*builtinInstance)) (Instance
 			eof:       false,
 			batchSize: sdk.DefaultBatchSize,
 			eventSize: sdk.DefaultEvtSize,
+			startedAt: time.Now(),
 		},
 	}
 
@@ -214,6 +368,7 @@ func (s *pullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 			}
 			return n, err
 		}
+		s.recordEvent(uint64(evts.Get(n).Writer().Len()))
 		n++
 	}
 
@@ -264,6 +419,7 @@ func NewPushInstance(evtC <-chan PushEvent, options ...func(*builtinInstance)) (
 			eof:       false,
 			batchSize: sdk.DefaultBatchSize,
 			eventSize: sdk.DefaultEvtSize,
+			startedAt: time.Now(),
 		},
 	}
 
@@ -335,6 +491,7 @@ func (s *pushInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters)
 			} else {
 				evts.Get(n).SetTimestamp(uint64(evt.Timestamp.UnixNano()))
 			}
+			s.recordEvent(uint64(len(evt.Data)))
 			n++
 		// timeout hits, so we flush a partial batch
 		case <-s.timeoutTicker.C:
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2023 The Diginfra Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReportThroughput(t *testing.T) {
+	report := ProgressReport{
+		BytesProcessed: 1000,
+		StartedAt:      time.Now().Add(-time.Second),
+	}
+	rate, ok := report.Throughput()
+	if !ok {
+		t.Fatalf("expected a throughput to be computable")
+	}
+	if rate <= 0 {
+		t.Fatalf("expected a positive throughput, got %f", rate)
+	}
+
+	if _, ok := (ProgressReport{}).Throughput(); ok {
+		t.Fatalf("expected no throughput without a StartedAt")
+	}
+}
+
+func TestProgressReportETA(t *testing.T) {
+	report := ProgressReport{
+		BytesProcessed: 500,
+		BytesTotal:     1000,
+		StartedAt:      time.Now().Add(-time.Second),
+	}
+	if _, ok := report.ETA(); !ok {
+		t.Fatalf("expected an ETA to be computable")
+	}
+
+	done := ProgressReport{BytesProcessed: 1000, BytesTotal: 1000, StartedAt: time.Now().Add(-time.Second)}
+	if _, ok := done.ETA(); ok {
+		t.Fatalf("expected no ETA once BytesTotal is reached")
+	}
+
+	if _, ok := (ProgressReport{}).ETA(); ok {
+		t.Fatalf("expected no ETA without a BytesTotal")
+	}
+
+	// 900 bytes processed in 1s at a steady rate leaves 100 bytes, i.e. a
+	// sub-second remainder; truncating to whole seconds before converting
+	// to a time.Duration would wrongly report an ETA of 0.
+	subSecond := ProgressReport{
+		BytesProcessed: 900,
+		BytesTotal:     1000,
+		StartedAt:      time.Now().Add(-time.Second),
+	}
+	eta, ok := subSecond.ETA()
+	if !ok {
+		t.Fatalf("expected an ETA to be computable")
+	}
+	if eta <= 0 || eta >= time.Second {
+		t.Fatalf("expected a sub-second ETA, got %v", eta)
+	}
+}
+
+func TestProgressReportString(t *testing.T) {
+	report := ProgressReport{
+		Fraction:        0.5,
+		BytesProcessed:  512,
+		BytesTotal:      1024,
+		EventsProcessed: 7,
+		Labels:          map[string]string{"source": "kafka"},
+	}
+	str := report.String()
+
+	for _, want := range []string{"50.00%", "512/1024 bytes", "7 events", "source=kafka"} {
+		if !strings.Contains(str, want) {
+			t.Errorf("expected String() output %q to contain %q", str, want)
+		}
+	}
+}
+
+func TestProgressReportStringMinimal(t *testing.T) {
+	str := (ProgressReport{Fraction: 0.1}).String()
+	if str != "10.00%" {
+		t.Errorf("expected minimal String() output to be just the fraction, got %q", str)
+	}
+}
@@ -0,0 +1,319 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2023 The Diginfra Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diginfra/plugin-sdk-go/pkg/sdk"
+)
+
+// MultiplexOption configures an instance created with NewMultiplexInstance.
+type MultiplexOption func(*multiplexInstance)
+
+// WithMultiplexInstanceOption wraps a regular builtinInstance option, such as
+// WithInstanceContext or WithInstanceTimeout, so that it can be passed to
+// NewMultiplexInstance alongside multiplex-specific options.
+func WithMultiplexInstanceOption(opt func(*builtinInstance)) MultiplexOption {
+	return func(m *multiplexInstance) {
+		opt(&m.builtinInstance)
+	}
+}
+
+// WithMultiplexFailFast makes the multiplexed instance return sdk.ErrEOF as
+// soon as any one of its children reaches EOF. The default behavior is to
+// keep draining the remaining children until every one of them has reached
+// EOF.
+func WithMultiplexFailFast() MultiplexOption {
+	return func(m *multiplexInstance) {
+		m.failFast = true
+	}
+}
+
+// WithMultiplexWeights assigns a relative weight to each child source, in
+// the same order sources are passed to NewMultiplexInstance. A child with
+// weight 4 is drained up to 4 events for every 1 event drained from a child
+// of weight 1. Children default to a weight of 1 when this option is not
+// used, or for any child beyond the end of weights.
+func WithMultiplexWeights(weights ...int) MultiplexOption {
+	return func(m *multiplexInstance) {
+		m.weights = weights
+	}
+}
+
+// multiplexChild tracks the deficit round-robin state of one composed
+// source.Instance.
+type multiplexChild struct {
+	instance Instance
+	weight   int
+	deficit  int
+	eof      bool
+}
+
+type multiplexInstance struct {
+	builtinInstance
+	children []*multiplexChild
+	weights  []int
+	failFast bool
+	next     int
+}
+
+// NewMultiplexInstance composes several source.Instance values, pull or push
+// alike, into a single Instance whose NextBatch fair round-robins across
+// them and merges their events into one caller-provided batch.
+//
+// This lets a plugin ingest several heterogeneous event sources, e.g. a
+// Kafka topic plus a file tail plus a webhook, without hand-rolling its own
+// fan-in goroutine for every combination of source kinds.
+//
+// By default, NextBatch only returns sdk.ErrEOF once every child has
+// reached EOF; pass WithMultiplexFailFast to invert that policy. A child
+// returning sdk.ErrTimeout is skipped in favor of the next one instead of
+// stalling the whole batch. Pass WithMultiplexWeights to favor some
+// children over others through a deficit round-robin schedule, so that
+// noisy sources don't starve quieter ones.
+//
+// Close forwards to every child. Progress reports the average fraction
+// across children, with a per-child breakdown in the string component,
+// unless overridden with WithMultiplexInstanceOption(WithInstanceProgress(...))
+// or WithInstanceProgressReporter(...), in which case it behaves like any
+// other builtinInstance and the per-child breakdown is not computed.
+//
+// Like NewPullInstance and NewParallelPullInstance, NextBatch respects the
+// configured timeout: pass WithMultiplexInstanceOption(WithInstanceTimeout)
+// to change it, since idle children reporting sdk.ErrTimeout are otherwise
+// the steady state rather than the exception.
+//
+// Each child is always driven through a window carved out of the
+// multiplex's own batch, sized from WithMultiplexInstanceOption(
+// WithInstanceBatchSize(...)/WithInstanceEventSize(...)) (or their
+// defaults), never from whatever the child was constructed with: a child's
+// own pre-allocated batch is never touched once it is passed here. Size the
+// multiplex's event size for the largest event any child can produce, or
+// that child's writes will fail against the smaller per-slot capacity.
+func NewMultiplexInstance(sources []Instance, options ...MultiplexOption) (Instance, error) {
+	res := &multiplexInstance{
+		builtinInstance: builtinInstance{
+			ctx:       context.Background(),
+			timeout:   defaultInstanceTimeout,
+			shutdown:  func() {},
+			eof:       false,
+			batchSize: sdk.DefaultBatchSize,
+			eventSize: sdk.DefaultEvtSize,
+			startedAt: time.Now(),
+		},
+	}
+
+	for _, opt := range options {
+		opt(res)
+	}
+
+	res.children = make([]*multiplexChild, len(sources))
+	for i, src := range sources {
+		weight := 1
+		if i < len(res.weights) && res.weights[i] > 0 {
+			weight = res.weights[i]
+		}
+		res.children[i] = &multiplexChild{instance: src, weight: weight}
+	}
+
+	// create custom-sized event batch
+	batch, err := sdk.NewEventWriters(int64(res.batchSize), int64(res.eventSize))
+	if err != nil {
+		return nil, err
+	}
+	res.SetEvents(batch)
+
+	// init timer
+	res.timeoutTicker = time.NewTicker(res.timeout)
+
+	// setup internally-cancellable context
+	prevCancel := res.shutdown
+	cancelableCtx, cancelCtx := context.WithCancel(res.ctx)
+	res.ctx = cancelableCtx
+	res.shutdown = func() {
+		cancelCtx()
+		prevCancel()
+	}
+
+	return res, nil
+}
+
+func (m *multiplexInstance) Close() {
+	for _, c := range m.children {
+		c.instance.Close()
+	}
+	m.builtinInstance.Close()
+}
+
+func (m *multiplexInstance) Progress(pState sdk.PluginState) (float64, string) {
+	// a custom progress callback or reporter set through
+	// WithMultiplexInstanceOption(WithInstanceProgress(...)/
+	// WithInstanceProgressReporter(...)) overrides the default
+	// average-across-children behavior below, same as for any other
+	// builtinInstance.
+	if m.progressReporter != nil || m.progress != nil {
+		return m.builtinInstance.Progress(pState)
+	}
+
+	if len(m.children) == 0 {
+		return 0, ""
+	}
+
+	var sum float64
+	parts := make([]string, len(m.children))
+	for i, c := range m.children {
+		frac, str := c.instance.Progress(pState)
+		sum += frac
+		parts[i] = fmt.Sprintf("source %d: %s", i, str)
+	}
+
+	return sum / float64(len(m.children)), strings.Join(parts, ", ")
+}
+
+func (m *multiplexInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (int, error) {
+	if m.eof {
+		return 0, sdk.ErrEOF
+	}
+
+	// timeout needs to be resetted for this batch
+	m.timeoutTicker.Reset(m.timeout)
+
+	total := evts.Len()
+	n := 0
+
+	for n < total {
+		// check if we should return before draining another child; children
+		// idling and returning sdk.ErrTimeout on every call is the normal
+		// steady state, not an edge case, so this bounds NextBatch to
+		// m.timeout the same way the sequential and parallel pull instances
+		// are bounded
+		select {
+		case <-m.timeoutTicker.C:
+			return n, sdk.ErrTimeout
+		case <-m.ctx.Done():
+			m.eof = true
+			return n, sdk.ErrEOF
+		default:
+		}
+
+		if m.allChildrenEOF() {
+			m.eof = true
+			return n, sdk.ErrEOF
+		}
+
+		child := m.nextChild()
+		if child == nil {
+			// every non-EOF child has exhausted its credit for this round;
+			// start a new round and keep going
+			m.replenish()
+			continue
+		}
+
+		window := child.deficit
+		if remaining := total - n; window > remaining {
+			window = remaining
+		}
+
+		cn, cerr := child.instance.NextBatch(pState, &windowedEventWriters{
+			EventWriters: evts,
+			offset:       n,
+			length:       window,
+		})
+		for i := n; i < n+cn; i++ {
+			m.recordEvent(uint64(evts.Get(i).Writer().Len()))
+		}
+		n += cn
+		child.deficit -= cn
+
+		switch cerr {
+		case nil:
+			// child filled its window; it gets more credit on the next round
+		case sdk.ErrTimeout:
+			// nothing ready right now, skip to the next child
+			child.deficit = 0
+		case sdk.ErrEOF:
+			child.eof = true
+			child.deficit = 0
+			if m.failFast {
+				m.eof = true
+				return n, sdk.ErrEOF
+			}
+		default:
+			m.eof = true
+			return n, cerr
+		}
+	}
+
+	return n, nil
+}
+
+// nextChild returns the next non-EOF child with remaining deficit in the
+// current round, advancing the round-robin cursor. It returns nil once no
+// child has credit left, signaling the caller to start a new round.
+func (m *multiplexInstance) nextChild() *multiplexChild {
+	for i := 0; i < len(m.children); i++ {
+		idx := (m.next + i) % len(m.children)
+		c := m.children[idx]
+		if c.eof || c.deficit <= 0 {
+			continue
+		}
+		m.next = (idx + 1) % len(m.children)
+		return c
+	}
+	return nil
+}
+
+// replenish starts a new deficit round-robin round by crediting every
+// non-EOF child with its weight.
+func (m *multiplexInstance) replenish() {
+	for _, c := range m.children {
+		if !c.eof {
+			c.deficit += c.weight
+		}
+	}
+}
+
+func (m *multiplexInstance) allChildrenEOF() bool {
+	for _, c := range m.children {
+		if !c.eof {
+			return false
+		}
+	}
+	return true
+}
+
+// windowedEventWriters exposes a contiguous sub-range of a sdk.EventWriters
+// batch as if it were a batch of its own, so that a child source.Instance
+// can be handed a slice of the parent batch to fill in place.
+type windowedEventWriters struct {
+	sdk.EventWriters
+	offset int
+	length int
+}
+
+func (w *windowedEventWriters) Len() int {
+	return w.length
+}
+
+func (w *windowedEventWriters) Get(i int) sdk.EventWriter {
+	return w.EventWriters.Get(w.offset + i)
+}
@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2023 The Diginfra Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diginfra/plugin-sdk-go/pkg/sdk"
+)
+
+// countingPullInstance returns a pull-model Instance that produces up to
+// limit events before reporting sdk.ErrEOF, counting how many of its own
+// events were actually pulled.
+func countingPullInstance(t *testing.T, limit int, produced *int64) Instance {
+	t.Helper()
+	var n int64
+	inst, err := NewPullInstance(func(ctx context.Context, evt sdk.EventWriter) error {
+		if atomic.LoadInt64(&n) >= int64(limit) {
+			return sdk.ErrEOF
+		}
+		atomic.AddInt64(&n, 1)
+		if produced != nil {
+			atomic.AddInt64(produced, 1)
+		}
+		_, err := evt.Writer().Write([]byte("x"))
+		return err
+	}, WithInstanceBatchSize(1))
+	if err != nil {
+		t.Fatalf("NewPullInstance: %v", err)
+	}
+	return inst
+}
+
+func TestMultiplexInstanceMergesUntilAllEOF(t *testing.T) {
+	a := countingPullInstance(t, 3, nil)
+	b := countingPullInstance(t, 2, nil)
+	defer a.Close()
+	defer b.Close()
+
+	inst, err := NewMultiplexInstance([]Instance{a, b}, WithMultiplexInstanceOption(WithInstanceBatchSize(8)))
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	total := 0
+	for {
+		n, err := inst.NextBatch(pState, inst.Events())
+		total += n
+		if err == sdk.ErrEOF {
+			break
+		}
+		if err != nil && err != sdk.ErrTimeout {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if total != 5 {
+		t.Fatalf("expected 5 merged events across both children, got %d", total)
+	}
+}
+
+func TestMultiplexInstanceFailFastStopsOnFirstEOF(t *testing.T) {
+	a := countingPullInstance(t, 0, nil) // EOF immediately
+	b := countingPullInstance(t, 100, nil)
+	defer a.Close()
+	defer b.Close()
+
+	inst, err := NewMultiplexInstance(
+		[]Instance{a, b},
+		WithMultiplexInstanceOption(WithInstanceBatchSize(8)),
+		WithMultiplexFailFast(),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	if _, err := inst.NextBatch(pState, inst.Events()); err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF as soon as one child is exhausted, got %v", err)
+	}
+}
+
+func TestMultiplexInstanceWeightsFavorHeavierChild(t *testing.T) {
+	var producedA, producedB int64
+	a := countingPullInstance(t, 1000, &producedA)
+	b := countingPullInstance(t, 1000, &producedB)
+	defer a.Close()
+	defer b.Close()
+
+	inst, err := NewMultiplexInstance(
+		[]Instance{a, b},
+		WithMultiplexInstanceOption(WithInstanceBatchSize(10)),
+		WithMultiplexWeights(4, 1),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	n, err := inst.NextBatch(pState, inst.Events())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 10 {
+		t.Fatalf("expected a full batch of 10, got %d", n)
+	}
+	// weight sum is 5, so 10 slots span two full deficit round-robin
+	// rounds: (4+4) for the weight-4 child against (1+1) for the other.
+	if producedA != 8 || producedB != 2 {
+		t.Fatalf("expected a 4:1 deficit round-robin split, got a=%d b=%d", producedA, producedB)
+	}
+}
+
+func TestMultiplexInstanceBoundedByTimeout(t *testing.T) {
+	idle := make(chan PushEvent)
+	defer close(idle)
+	idleChild, err := NewPushInstance(idle, WithInstanceBatchSize(4), WithInstanceTimeout(5*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewPushInstance: %v", err)
+	}
+	defer idleChild.Close()
+
+	inst, err := NewMultiplexInstance(
+		[]Instance{idleChild},
+		WithMultiplexInstanceOption(WithInstanceBatchSize(4)),
+		WithMultiplexInstanceOption(WithInstanceTimeout(20*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	done := make(chan struct{})
+	var pState sdk.PluginState
+	go func() {
+		defer close(done)
+		if _, err := inst.NextBatch(pState, inst.Events()); err != sdk.ErrTimeout {
+			t.Errorf("expected sdk.ErrTimeout from an idle child, got %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("NextBatch did not honor the configured timeout and is still blocked")
+	}
+}
+
+func TestMultiplexInstanceProgressReporterOverridesAverage(t *testing.T) {
+	var produced int64
+	childA := countingPullInstance(t, 4, &produced)
+	childB := countingPullInstance(t, 4, &produced)
+	defer childA.Close()
+	defer childB.Close()
+
+	inst, err := NewMultiplexInstance(
+		[]Instance{childA, childB},
+		WithMultiplexInstanceOption(WithInstanceBatchSize(4)),
+		WithMultiplexInstanceOption(WithInstanceProgressReporter(func() ProgressReport {
+			return ProgressReport{Fraction: 0.5, Labels: map[string]string{"custom": "yes"}}
+		})),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	frac, str := inst.Progress(sdk.PluginState{})
+	if frac != 0.5 {
+		t.Fatalf("expected the configured reporter's fraction to win over the per-child average, got %v", frac)
+	}
+	if !strings.Contains(str, "custom=yes") {
+		t.Fatalf("expected the configured reporter's labels in the string, got %q", str)
+	}
+}
+
+func TestMultiplexInstanceTracksEventsAndBytesProcessed(t *testing.T) {
+	childA := countingPullInstance(t, 4, nil)
+	childB := countingPullInstance(t, 4, nil)
+	defer childA.Close()
+	defer childB.Close()
+
+	inst, err := NewMultiplexInstance(
+		[]Instance{childA, childB},
+		WithMultiplexInstanceOption(WithInstanceBatchSize(8)),
+		// a reporter that only sets Fraction relies on fillAutoFields to
+		// backfill EventsProcessed/BytesProcessed from the instance's own
+		// counters, same as any other builtinInstance.
+		WithMultiplexInstanceOption(WithInstanceProgressReporter(func() ProgressReport {
+			return ProgressReport{Fraction: 1}
+		})),
+	)
+	if err != nil {
+		t.Fatalf("NewMultiplexInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	n, err := inst.NextBatch(pState, inst.Events())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 8 {
+		t.Fatalf("expected a full batch of 8, got %d", n)
+	}
+
+	_, str := inst.Progress(pState)
+	if !strings.Contains(str, "8 events") {
+		t.Fatalf("expected EventsProcessed to be tracked from merged children, got %q", str)
+	}
+	if !strings.Contains(str, "8 bytes") {
+		t.Fatalf("expected BytesProcessed to be tracked from merged children, got %q", str)
+	}
+}
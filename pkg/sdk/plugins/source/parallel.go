@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2023 The Diginfra Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/diginfra/plugin-sdk-go/pkg/sdk"
+)
+
+// parallelSlot reports the outcome of a single worker pulling into slot idx
+// of the current batch.
+type parallelSlot struct {
+	idx int
+	err error
+}
+
+type parallelPullInstance struct {
+	builtinInstance
+	pull    PullFunc
+	workers int
+}
+
+// ParallelPullOption configures an instance created with
+// NewParallelPullInstance.
+type ParallelPullOption func(*parallelPullInstance)
+
+// WithParallelInstanceOption wraps a regular builtinInstance option, such as
+// WithInstanceContext or WithInstanceTimeout, so that it can be passed to
+// NewParallelPullInstance alongside parallel-specific options.
+func WithParallelInstanceOption(opt func(*builtinInstance)) ParallelPullOption {
+	return func(p *parallelPullInstance) {
+		opt(&p.builtinInstance)
+	}
+}
+
+// NewParallelPullInstance opens a new event source and starts a capture
+// session like NewPullInstance, but invokes the PullFunc concurrently across
+// a fixed pool of workers instead of sequentially.
+//
+// This is meant for PullFunc implementations that spend most of their time
+// blocked on I/O (syscalls, HTTP requests, Kafka fetches, ...), where
+// sequential pulling forces the whole batch to serialize on the slowest
+// producer call. Each worker in the pool pulls into its own slot of the
+// batch and reports completion to a coordinator that assembles NextBatch()
+// results, while preserving the timeout, EOF and cancellation contract of
+// NewPullInstance.
+//
+// The workers argument sets the size of the worker pool; values less than 1
+// are treated as 1. Workers pull from a shared job queue as soon as they're
+// free, but a partial batch returned by a timeout or an error is always
+// reported as a contiguous prefix starting at slot 0: a worker that finishes
+// a later slot before an earlier one is still outstanding leaves that event
+// sitting past the reported count rather than having it compacted forward,
+// so NextBatch never hands the caller a count that skips a gap.
+//
+// On the first non-timeout error returned by any worker, the in-flight
+// round is cancelled and drained, and the event source is marked as having
+// reached EOF, exactly like the sequential path.
+func NewParallelPullInstance(pull PullFunc, workers int, options ...ParallelPullOption) (Instance, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	res := &parallelPullInstance{
+		pull:    pull,
+		workers: workers,
+		builtinInstance: builtinInstance{
+			ctx:       context.Background(),
+			timeout:   defaultInstanceTimeout,
+			shutdown:  func() {},
+			eof:       false,
+			batchSize: sdk.DefaultBatchSize,
+			eventSize: sdk.DefaultEvtSize,
+			startedAt: time.Now(),
+		},
+	}
+
+	// apply options
+	for _, opt := range options {
+		opt(res)
+	}
+
+	// create custom-sized event batch
+	batch, err := sdk.NewEventWriters(int64(res.batchSize), int64(res.eventSize))
+	if err != nil {
+		return nil, err
+	}
+	res.SetEvents(batch)
+
+	// init timer
+	res.timeoutTicker = time.NewTicker(res.timeout)
+
+	// setup internally-cancellable context
+	prevCancel := res.shutdown
+	cancelableCtx, cancelCtx := context.WithCancel(res.ctx)
+	res.ctx = cancelableCtx
+	res.shutdown = func() {
+		cancelCtx()
+		prevCancel()
+	}
+
+	// return opened instance
+	return res, nil
+}
+
+func (s *parallelPullInstance) NextBatch(pState sdk.PluginState, evts sdk.EventWriters) (n int, err error) {
+	// once EOF has been hit, we should return it at each new call of NextBatch
+	if s.eof {
+		return 0, sdk.ErrEOF
+	}
+
+	// timeout needs to be resetted for this batch
+	s.timeoutTicker.Reset(s.timeout)
+
+	// this round's context is cancelled as soon as we decide to return, so
+	// that a timeout or an error signals any pull still in flight to stop.
+	// Cancelling alone is not enough to make it safe to reuse evts on the
+	// next call though: a worker can be blocked inside s.pull on I/O that
+	// doesn't check roundCtx until its current call returns, so every
+	// return path below also waits for the whole pool to drain via
+	// awaitWorkers before handing evts back to the caller.
+	roundCtx, cancelRound := context.WithCancel(s.ctx)
+	defer cancelRound()
+
+	total := evts.Len()
+	jobs := make(chan int, total)
+	results := make(chan parallelSlot, total)
+	for i := 0; i < total; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	workers := s.workers
+	if workers > total {
+		workers = total
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results <- parallelSlot{idx: idx, err: s.pull(roundCtx, evts.Get(idx))}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// awaitWorkers cancels the round and blocks until every worker has
+	// returned from its current (and any remaining queued) call to s.pull,
+	// so that evts is only handed back once nothing can still write into
+	// it. Results produced while draining are discarded: the caller is
+	// about to be told this round ended early, so anything beyond what was
+	// already committed is not part of the reported batch.
+	awaitWorkers := func() {
+		cancelRound()
+		for range results {
+		}
+	}
+
+	done := make([]bool, total)
+	committed := 0
+
+	for {
+		select {
+		case <-s.timeoutTicker.C:
+			awaitWorkers()
+			return committed, sdk.ErrTimeout
+		case <-s.ctx.Done():
+			awaitWorkers()
+			s.eof = true
+			return committed, sdk.ErrEOF
+		case res, ok := <-results:
+			if !ok {
+				// every worker finished and the channel was drained without
+				// filling the whole batch; this can only happen if total
+				// was 0
+				return committed, nil
+			}
+
+			if res.err != nil {
+				if res.err != sdk.ErrTimeout {
+					// in case of non-timeout error, we consider the event
+					// source ended, mirroring the sequential path
+					s.eof = true
+				}
+				// the worker produced no event for this slot, whether it
+				// timed out or failed, so flush whatever is already
+				// committed instead of counting it as processed
+				awaitWorkers()
+				return committed, res.err
+			}
+
+			s.recordEvent(uint64(evts.Get(res.idx).Writer().Len()))
+			done[res.idx] = true
+
+			// regardless of order, a worker always writes into the slot it
+			// was dispatched for, so the batch can only ever be safely
+			// reported up to the longest contiguous run of done slots
+			// starting at 0; anything past a gap is real data sitting at a
+			// higher index than the caller is told to look at, and would
+			// be read back out of the batch as garbage/stale on the next
+			// round
+			for committed < total && done[committed] {
+				committed++
+			}
+
+			if committed == total {
+				return committed, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+/*
+Copyright (C) 2023 The Diginfra Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diginfra/plugin-sdk-go/pkg/sdk"
+)
+
+func TestParallelPullInstanceFillsBatch(t *testing.T) {
+	var next int64
+	pull := func(ctx context.Context, evt sdk.EventWriter) error {
+		idx := atomic.AddInt64(&next, 1) - 1
+		if idx >= 8 {
+			return sdk.ErrEOF
+		}
+		_, err := evt.Writer().Write([]byte("x"))
+		return err
+	}
+
+	inst, err := NewParallelPullInstance(pull, 4, WithParallelInstanceOption(WithInstanceBatchSize(8)))
+	if err != nil {
+		t.Fatalf("NewParallelPullInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	n, err := inst.NextBatch(pState, inst.Events())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 8 {
+		t.Fatalf("expected a full batch of 8, got %d", n)
+	}
+}
+
+func TestParallelPullInstanceTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	pull := func(ctx context.Context, evt sdk.EventWriter) error {
+		select {
+		case <-block:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	inst, err := NewParallelPullInstance(
+		pull, 4,
+		WithParallelInstanceOption(WithInstanceBatchSize(8)),
+		WithParallelInstanceOption(WithInstanceTimeout(10*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatalf("NewParallelPullInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	n, err := inst.NextBatch(pState, inst.Events())
+	if err != sdk.ErrTimeout {
+		t.Fatalf("expected sdk.ErrTimeout, got %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no committed events, got %d", n)
+	}
+
+	// NextBatch must not return until every worker of the abandoned round
+	// has actually stopped, so reusing the same batch on the next call
+	// must not race with a straggler still writing into it.
+	n, err = inst.NextBatch(pState, inst.Events())
+	if err != sdk.ErrTimeout {
+		t.Fatalf("expected sdk.ErrTimeout on the second round too, got %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected no committed events, got %d", n)
+	}
+}
+
+func TestParallelPullInstanceOutOfOrderCompletionStillYieldsContiguousPrefix(t *testing.T) {
+	// the first pull call to start is the slowest to complete (delays are
+	// picked by call order, which usually but not necessarily matches slot
+	// order, so this is typically slot 0), so later calls finish first;
+	// NextBatch must still only report a count that is safe to read back as
+	// a contiguous run from 0, even though the underlying completions
+	// arrived out of order.
+	delays := []time.Duration{30 * time.Millisecond, 0, 20 * time.Millisecond, 0}
+	var idx int64
+	dispatch := func(ctx context.Context, evt sdk.EventWriter) error {
+		i := int(atomic.AddInt64(&idx, 1) - 1)
+		select {
+		case <-time.After(delays[i%len(delays)]):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		_, err := evt.Writer().Write([]byte("x"))
+		return err
+	}
+
+	inst, err := NewParallelPullInstance(
+		dispatch, 4,
+		WithParallelInstanceOption(WithInstanceBatchSize(4)),
+	)
+	if err != nil {
+		t.Fatalf("NewParallelPullInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	n, err := inst.NextBatch(pState, inst.Events())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 4 {
+		t.Fatalf("expected a full batch of 4 once every slot lands, got %d", n)
+	}
+}
+
+func TestParallelPullInstanceEOFPersists(t *testing.T) {
+	pull := func(ctx context.Context, evt sdk.EventWriter) error {
+		return sdk.ErrEOF
+	}
+
+	inst, err := NewParallelPullInstance(pull, 2, WithParallelInstanceOption(WithInstanceBatchSize(4)))
+	if err != nil {
+		t.Fatalf("NewParallelPullInstance: %v", err)
+	}
+	defer inst.Close()
+
+	var pState sdk.PluginState
+	if _, err := inst.NextBatch(pState, inst.Events()); err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF, got %v", err)
+	}
+	if _, err := inst.NextBatch(pState, inst.Events()); err != sdk.ErrEOF {
+		t.Fatalf("expected sdk.ErrEOF to persist, got %v", err)
+	}
+}